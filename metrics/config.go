@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"flag"
+	"time"
+
+	nats "github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Config struct holds all the runtime configuration for a metrics run
+type Config struct {
+	org  string
+	repo string
+	url  string
+
+	user  string
+	token string
+
+	insecure bool
+	once     bool
+	preview  bool
+	interval time.Duration
+
+	output string
+	csvSep string
+
+	prerelease bool
+	patch      bool
+	minor      bool
+	match      string
+
+	influxVersion string
+	influxurl     string
+	influxdb      string
+	influxuser    string
+	influxpass    string
+	influxorg     string
+	influxbucket  string
+	influxtoken   string
+	batch         int
+	flush         int
+
+	listenAddr string
+
+	retryLimit int
+	retryBase  time.Duration
+	retryCap   time.Duration
+
+	configFile  string
+	targets     []Target
+	concurrency int
+
+	natsurl           string
+	natsstream        string
+	natssubjectprefix string
+}
+
+// NewConfig parses the command line flags into a Config
+func NewConfig() *Config {
+	c := &Config{}
+
+	flag.StringVar(&c.org, "org", "", "Github organization")
+	flag.StringVar(&c.repo, "repo", "", "Github repository")
+	flag.StringVar(&c.url, "url", "https://api.github.com/repos", "Github API url")
+
+	flag.StringVar(&c.user, "user", "", "Github user")
+	flag.StringVar(&c.token, "token", "", "Github token")
+
+	flag.BoolVar(&c.insecure, "insecure", false, "Skip TLS verification")
+	flag.BoolVar(&c.once, "once", false, "Run a single collection pass and exit (ignored by the prometheus output)")
+	flag.BoolVar(&c.preview, "preview", false, "Print metrics instead of sending them")
+	flag.DurationVar(&c.interval, "interval", time.Minute*5, "Interval between collections")
+
+	flag.StringVar(&c.output, "output", "json", "Output type: json, csv, influx, prometheus, nats")
+	flag.StringVar(&c.csvSep, "csv-separator", ",", "CSV field separator")
+
+	flag.BoolVar(&c.prerelease, "prerelease", false, "Include prereleases")
+	flag.BoolVar(&c.patch, "patch", false, "Aggregate releases by patch version")
+	flag.BoolVar(&c.minor, "minor", false, "Aggregate releases by minor version")
+	flag.StringVar(&c.match, "match", "", "Glob to filter release assets")
+
+	flag.StringVar(&c.influxVersion, "influx-version", "v1", "InfluxDB client version to use: v1 or v2")
+	flag.StringVar(&c.influxurl, "influx-url", "", "InfluxDB url")
+	flag.StringVar(&c.influxdb, "influx-db", "", "InfluxDB database (v1)")
+	flag.StringVar(&c.influxuser, "influx-user", "", "InfluxDB user (v1)")
+	flag.StringVar(&c.influxpass, "influx-pass", "", "InfluxDB password (v1)")
+	flag.StringVar(&c.influxorg, "influx-org", "", "InfluxDB organization (v2)")
+	flag.StringVar(&c.influxbucket, "influx-bucket", "", "InfluxDB bucket (v2)")
+	flag.StringVar(&c.influxtoken, "influx-token", "", "InfluxDB auth token (v2)")
+	flag.IntVar(&c.batch, "batch", 100, "Number of points per Influx batch")
+	flag.IntVar(&c.flush, "flush", 10, "Seconds between Influx batch flushes")
+
+	flag.StringVar(&c.listenAddr, "listen-addr", ":9090", "Listen address for the Prometheus /metrics endpoint")
+
+	flag.IntVar(&c.retryLimit, "retry-limit", 5, "Max attempts for a Github API call before giving up on the tick")
+	flag.DurationVar(&c.retryBase, "retry-base", time.Second, "Base delay for exponential backoff retries")
+	flag.DurationVar(&c.retryCap, "retry-cap", time.Minute*2, "Maximum exponential backoff delay between retries, before jitter; rate-limit waits are not capped by it")
+
+	flag.StringVar(&c.configFile, "config", "", "YAML file listing multiple org/repo targets to watch")
+	flag.IntVar(&c.concurrency, "concurrency", 10, "Max number of Github API calls in flight at once")
+
+	flag.StringVar(&c.natsurl, "nats-url", nats.DefaultURL, "NATS server url")
+	flag.StringVar(&c.natsstream, "nats-stream", "github-metrics", "JetStream stream name metrics are published to")
+	flag.StringVar(&c.natssubjectprefix, "nats-subject-prefix", "github.metrics", "Subject prefix metrics are published under; the stream subscribes to prefix+\".>\"")
+
+	flag.Parse()
+
+	if c.configFile != "" {
+		targets, err := loadTargets(c.configFile)
+		if err != nil {
+			log.Fatal("Error loading --config ", c.configFile, ": ", err)
+		}
+		c.targets = targets
+	}
+
+	return c
+}
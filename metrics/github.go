@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+var nextLinkRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// httpStatusError captures the response status and headers needed to decide
+// whether and how long to back off, without re-parsing the response body.
+type httpStatusError struct {
+	status  int
+	headers http.Header
+}
+
+func (e *httpStatusError) Error() string {
+	return "unexpected http status " + strconv.Itoa(e.status)
+}
+
+// getJSON performs an authenticated GET against the Github API, decodes the
+// response body into target and returns the "next" pagination url, if any.
+// A response with status 400 or above is returned as an *httpStatusError so callers can
+// inspect rate-limit headers.
+func getJSON(ctx context.Context, url, user, token string, insecure bool, target interface{}) (string, error) {
+	client := &http.Client{}
+	if insecure {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	} else if user != "" {
+		req.SetBasicAuth(user, token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", &httpStatusError{status: resp.StatusCode, headers: resp.Header}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return "", err
+	}
+
+	return nextLink(resp.Header.Get("Link")), nil
+}
+
+func nextLink(link string) string {
+	m := nextLinkRe.FindStringSubmatch(link)
+	if len(m) != 2 {
+		return ""
+	}
+	return m[1]
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import "fmt"
+
+// InfluxWriter is the common interface the v1 and v2 Influx backends (and
+// the batching loop in sendToInflux) share, so adding a third backend is
+// just a matter of implementing it.
+type InfluxWriter interface {
+	Write(points []Point) bool
+	Flush() bool
+	Close()
+}
+
+// newInfluxWriter builds the configured Influx backend.
+func newInfluxWriter(cfg *Config) (InfluxWriter, error) {
+	switch cfg.influxVersion {
+	case "", "v1":
+		writer, err := newInfluxV1(cfg.influxurl, cfg.influxdb, cfg.influxuser, cfg.influxpass)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case "v2":
+		return newInfluxV2(cfg.influxurl, cfg.influxorg, cfg.influxbucket, cfg.influxtoken), nil
+	default:
+		return nil, fmt.Errorf("unknown influx-version %q", cfg.influxVersion)
+	}
+}
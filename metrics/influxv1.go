@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"time"
+
+	influx "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// influxV1 writes points to InfluxDB's v1 HTTP API.
+type influxV1 struct {
+	url  string
+	db   string
+	user string
+	pass string
+
+	client influx.Client
+}
+
+func newInfluxV1(url, db, user, pass string) (*influxV1, error) {
+	i := &influxV1{
+		url:  url,
+		db:   db,
+		user: user,
+		pass: pass,
+	}
+
+	client, err := influx.NewHTTPClient(influx.HTTPConfig{
+		Addr:     i.url,
+		Username: i.user,
+		Password: i.pass,
+	})
+	if err != nil {
+		return nil, err
+	}
+	i.client = client
+
+	return i, nil
+}
+
+func (i *influxV1) Write(points []Point) bool {
+	bp, err := influx.NewBatchPoints(influx.BatchPointsConfig{Database: i.db})
+	if err != nil {
+		log.Error("Error creating Influx batch ", err)
+		return false
+	}
+
+	for _, p := range points {
+		t := p.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		pt, err := influx.NewPoint(p.Measurement, p.Tags, p.Fields, t)
+		if err != nil {
+			log.Error("Error building Influx point ", err)
+			continue
+		}
+		bp.AddPoint(pt)
+	}
+
+	if err := i.client.Write(bp); err != nil {
+		log.Error("Error writing to Influx ", err)
+		return false
+	}
+
+	return true
+}
+
+// Flush is a no-op for v1: writes are synchronous.
+func (i *influxV1) Flush() bool {
+	return true
+}
+
+func (i *influxV1) Close() {
+	if i.client != nil {
+		i.client.Close()
+	}
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	log "github.com/sirupsen/logrus"
+)
+
+// influxV2 writes points to InfluxDB v2 using the async, gzip-batching
+// WriteAPI, authenticating with an org/bucket/token instead of v1's
+// database/user/password.
+type influxV2 struct {
+	client influxdb2.Client
+	write  api.WriteAPI
+}
+
+func newInfluxV2(url, org, bucket, token string) *influxV2 {
+	client := influxdb2.NewClient(url, token)
+
+	i := &influxV2{
+		client: client,
+		write:  client.WriteAPI(org, bucket),
+	}
+
+	go func() {
+		for err := range i.write.Errors() {
+			log.Error("Error writing to Influx v2 ", err)
+		}
+	}()
+
+	return i
+}
+
+func (i *influxV2) Write(points []Point) bool {
+	for _, p := range points {
+		t := p.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		i.write.WritePoint(influxdb2.NewPoint(p.Measurement, p.Tags, p.Fields, t))
+	}
+
+	return true
+}
+
+// Flush blocks until the WriteAPI's pending async batch is sent.
+func (i *influxV2) Flush() bool {
+	i.write.Flush()
+	return true
+}
+
+func (i *influxV2) Close() {
+	i.write.Flush()
+	i.client.Close()
+}
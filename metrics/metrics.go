@@ -1,93 +1,91 @@
 package metrics
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
-	// Blank import required by vendor
-	_ "github.com/influxdata/influxdb1-client"
-	influx "github.com/influxdata/influxdb1-client/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
-const (
-	influxCheck = 3600
-)
-
 var csvSeparator string
+var natsSubjectPrefix string
 
 // Metric interface
 type Metric interface {
 	printJSON()
 	printCSV()
 	printInflux()
-	getPoint() []influx.Point
+	getPoint() []Point
+	registerPrometheus(registry *prometheus.Registry)
+	updatePrometheus()
+	subject() string
+	payload() ([]byte, error)
 }
 
 // Metrics struct
 type Metrics struct {
-	Input   chan Metric
-	Exit    chan os.Signal
-	Readers []chan struct{}
-	Config  *Config
+	Input  chan Metric
+	Exit   chan os.Signal
+	Config *Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sem chan struct{}
 }
 
 // NewMetrics function
 func NewMetrics(conf *Config) *Metrics {
 	r := &Metrics{
-		Readers: []chan struct{}{},
-		Config:  conf,
+		Config: conf,
 	}
 
 	r.Input = make(chan Metric, 1)
 	r.Exit = make(chan os.Signal, 1)
 	signal.Notify(r.Exit, os.Interrupt, os.Kill)
 
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	r.sem = make(chan struct{}, conf.concurrency)
+
 	customFormatter := new(log.TextFormatter)
 	customFormatter.TimestampFormat = "2006-01-02 15:04:05"
 	log.SetFormatter(customFormatter)
 	customFormatter.FullTimestamp = true
 
 	csvSeparator = r.Config.csvSep
+	natsSubjectPrefix = r.Config.natssubjectprefix
 
 	return r
 }
 
-func (r *Metrics) addReader() chan struct{} {
-	chanNew := make(chan struct{}, 1)
-	r.Readers = append(r.Readers, chanNew)
-
-	return chanNew
-}
-
-func (r *Metrics) closeReaders() {
-	for _, rChan := range r.Readers {
-		if rChan != nil {
-			rChan <- struct{}{}
-		}
-	}
-	r.Readers = nil
-}
-
 // GetData gets all metrics data
 func (r *Metrics) GetData() {
+	defer r.cancel()
+
 	var in, out sync.WaitGroup
 	indone := make(chan struct{}, 1)
 	outdone := make(chan struct{}, 1)
 
-	in.Add(1)
-	go func() {
-		defer in.Done()
-		r.getRepoData(r.addReader())
-	}()
+	for _, target := range r.targets() {
+		target := target
 
-	in.Add(1)
-	go func() {
-		defer in.Done()
-		r.getReleaseData(r.addReader())
-	}()
+		in.Add(1)
+		go func() {
+			defer in.Done()
+			r.getRepoData(target)
+		}()
+
+		in.Add(1)
+		go func() {
+			defer in.Done()
+			r.getReleaseData(target)
+		}()
+	}
 
 	out.Add(1)
 	go func() {
@@ -109,87 +107,104 @@ func (r *Metrics) GetData() {
 	for {
 		select {
 		case <-indone:
-			go r.closeReaders()
 			<-outdone
 			return
 		case <-outdone:
 			log.Error("Aborting...")
-			go r.closeReaders()
+			r.cancel()
 			return
 		case <-r.Exit:
-			//close(r.Exit)
 			log.Info("Exit signal detected....Closing...")
-			go r.closeReaders()
-			select {
-			case <-outdone:
-				return
-			}
+			r.cancel()
+			<-outdone
+			return
 		}
 	}
 }
 
-func (r *Metrics) getRepoData(stop chan struct{}) {
-	r.getRepo()
+func (r *Metrics) getRepoData(target Target) {
+	r.getRepo(r.ctx, target)
 
-	if r.Config.once {
+	if r.Config.once && r.Config.output != "prometheus" {
 		return
 	}
 
+	// ticks tracks in-flight ticked fetches so getRepoData doesn't return
+	// (and let GetData close r.Input) while one is still about to send on it.
+	var ticks sync.WaitGroup
+	defer ticks.Wait()
+
 	ticker := time.NewTicker(r.Config.interval)
 
 	for {
 		select {
 		case <-ticker.C:
-			log.Info("Tick on getting repo data")
-			go r.getRepo()
-		case <-stop:
+			log.Infof("Tick on getting repo data for %s/%s", target.Org, target.Repo)
+			ticks.Add(1)
+			go func() {
+				defer ticks.Done()
+				r.getRepo(r.ctx, target)
+			}()
+		case <-r.ctx.Done():
 			return
 		}
 	}
 }
 
-func (r *Metrics) getRepo() {
-	uri := "/" + r.Config.org + "/" + r.Config.repo
+func (r *Metrics) getRepo(ctx context.Context, target Target) {
+	uri := "/" + target.Org + "/" + target.Repo
 
 	log.Infof("Getting repo data from %s...", r.Config.url+uri)
 
 	repo := &Repo{
-		Org: r.Config.org,
+		Org: target.Org,
 	}
 
-	_, err := getJSON(r.Config.url+uri, r.Config.user, r.Config.token, r.Config.insecure, repo)
+	r.sem <- struct{}{}
+	_, err := getJSONRetry(ctx, r.Config.url+uri, r.Config.user, r.Config.token, r.Config.insecure, repo, r.Config)
+	<-r.sem
 	if err != nil {
-		log.Error("Error getting repo JSON from ", r.Config.url+uri, err)
+		log.Error("Giving up getting repo JSON from ", r.Config.url+uri, " after retries: ", err)
+		return
 	}
 
 	r.Input <- repo
 }
 
-func (r *Metrics) getReleaseData(stop chan struct{}) {
-	r.getRelease(r.addReader())
+func (r *Metrics) getReleaseData(target Target) {
+	r.getRelease(r.ctx, target)
 
-	if r.Config.once {
+	if r.Config.once && r.Config.output != "prometheus" {
 		return
 	}
 
+	// ticks tracks in-flight ticked fetches so getReleaseData doesn't return
+	// (and let GetData close r.Input) while one is still about to send on it.
+	var ticks sync.WaitGroup
+	defer ticks.Wait()
+
 	ticker := time.NewTicker(r.Config.interval)
 
 	for {
 		select {
 		case <-ticker.C:
-			log.Debug("Tick on Getting release data")
-			go r.getRelease(r.addReader())
-		case <-stop:
+			log.Debugf("Tick on getting release data for %s/%s", target.Org, target.Repo)
+			ticks.Add(1)
+			go func() {
+				defer ticks.Done()
+				r.getRelease(r.ctx, target)
+			}()
+		case <-r.ctx.Done():
 			return
 		}
 	}
 }
 
-func (r *Metrics) getRelease(stop chan struct{}) {
+func (r *Metrics) getRelease(ctx context.Context, target Target) {
 	var err error
 	urlChan := make(chan string, 1)
 
-	uri := "/" + r.Config.org + "/" + r.Config.repo + "/releases"
+	uri := "/" + target.Org + "/" + target.Repo + "/releases"
 
 	log.Infof("Getting release data from %s...", r.Config.url+uri)
 
@@ -202,38 +217,43 @@ func (r *Metrics) getRelease(stop chan struct{}) {
 		case url := <-urlChan:
 			if url == "" {
 				close(urlChan)
-				r.filterReleases(releases)
+				r.filterReleases(target, releases)
 				return
 			}
 			nextRel := &[]Release{}
-			next, err = getJSON(url, r.Config.user, r.Config.token, r.Config.insecure, nextRel)
+			r.sem <- struct{}{}
+			next, err = getJSONRetry(ctx, url, r.Config.user, r.Config.token, r.Config.insecure, nextRel, r.Config)
+			<-r.sem
 			if err != nil {
-				log.Error("Getting release JSON from ", next, err)
+				log.Error("Giving up getting release JSON from ", url, " after retries: ", err)
+				close(urlChan)
+				r.filterReleases(target, releases)
+				return
 			}
 			*releases = append(*releases, *nextRel...)
 			urlChan <- next
-		case <-stop:
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (r *Metrics) filterReleases(releases *[]Release) {
+func (r *Metrics) filterReleases(target Target, releases *[]Release) {
 	filterReleases := map[string]*Release{}
 
 	for _, release := range *releases {
-		if !r.Config.prerelease && release.Prerelease {
+		if !target.effectivePrerelease(r.Config) && release.Prerelease {
 			continue
 		}
-		if r.Config.patch {
+		if target.effectivePatch(r.Config) {
 			release.Name = release.getName(releasePatch)
 		}
-		if r.Config.minor {
+		if target.effectiveMinor(r.Config) {
 			release.Name = release.getName(releaseMinor)
 		}
-		release.Org = r.Config.org
-		release.Repo = r.Config.repo
-		release.filterAssets(r.Config.match)
+		release.Org = target.Org
+		release.Repo = target.Repo
+		release.filterAssets(target.effectiveMatch(r.Config))
 
 		newRel := release
 		if _, exist := filterReleases[release.Name]; !exist {
@@ -243,6 +263,16 @@ func (r *Metrics) filterReleases(releases *[]Release) {
 		}
 	}
 
+	if r.Config.output == "prometheus" {
+		names := make([]string, 0, len(filterReleases))
+		for name, rel := range filterReleases {
+			if len(*rel.Assets) > 0 {
+				names = append(names, name)
+			}
+		}
+		reconcileReleasePrometheus(target.Org, target.Repo, names)
+	}
+
 	for _, rel := range filterReleases {
 		if len(*rel.Assets) > 0 {
 			input := rel
@@ -261,6 +291,10 @@ func (r *Metrics) getOutput() {
 		} else {
 			r.sendToInflux()
 		}
+	case "prometheus":
+		r.servePrometheus()
+	case "nats":
+		r.sendToNats()
 	}
 }
 
@@ -285,53 +319,54 @@ func (r *Metrics) print() {
 }
 
 func (r *Metrics) sendToInflux() {
-	var points []influx.Point
-	var index, pLen int
+	var points []Point
+	var pLen int
 
-	i := newInflux(r.Config.influxurl, r.Config.influxdb, r.Config.influxuser, r.Config.influxpass)
-
-	if i.Connect() {
-		connected := i.CheckConnect(influxCheck)
-		defer i.Close()
+	writer, err := newInfluxWriter(r.Config)
+	if err != nil {
+		log.Error("Error creating Influx writer ", err)
+		return
+	}
+	defer writer.Close()
 
-		ticker := time.NewTicker(time.Second * time.Duration(r.Config.flush))
+	ticker := time.NewTicker(time.Second * time.Duration(r.Config.flush))
 
-		index = 0
-		for {
-			select {
-			case <-connected:
-				return
-			case <-ticker.C:
-				if len(points) > 0 {
-					log.Debug("Tick on sending to influx")
-					if i.sendToInflux(points, 1) {
-						points = []influx.Point{}
+	for {
+		select {
+		case <-r.ctx.Done():
+			if len(points) > 0 {
+				writer.Write(points)
+			}
+			writer.Flush()
+			return
+		case <-ticker.C:
+			if len(points) > 0 {
+				log.Debug("Tick on sending to influx")
+				if writer.Write(points) {
+					points = []Point{}
+				} else {
+					return
+				}
+			}
+			writer.Flush()
+		case p := <-r.Input:
+			if p != nil {
+				points = append(points, p.getPoint()...)
+				pLen = len(points)
+				if pLen >= r.Config.batch {
+					if writer.Write(points) {
+						points = []Point{}
 					} else {
 						return
 					}
 				}
-			case p := <-r.Input:
-				if p != nil {
-					m := p.getPoint()
-					points = append(points, m...)
-					pLen = len(points)
-					if pLen == r.Config.batch {
-						if i.sendToInflux(points, 1) {
-							points = []influx.Point{}
-						} else {
-							return
-						}
-					}
-					index++
-				} else {
-					pLen = len(points)
-					if pLen > 0 {
-						if i.sendToInflux(points, 1) {
-							points = []influx.Point{}
-						}
-					}
-					return
+			} else {
+				pLen = len(points)
+				if pLen > 0 {
+					writer.Write(points)
 				}
+				writer.Flush()
+				return
 			}
 		}
 	}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"time"
+
+	nats "github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+)
+
+const natsReconnectWait = time.Second * 2
+
+// natsSink publishes metrics to a JetStream stream, reconnecting without
+// limit on connection loss so a NATS blip doesn't kill the collector. The
+// wait between reconnect attempts is a fixed natsReconnectWait; it does not
+// grow from one attempt to the next.
+type natsSink struct {
+	url           string
+	stream        string
+	subjectPrefix string
+
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+func newNatsSink(cfg *Config) (*natsSink, error) {
+	s := &natsSink{
+		url:           cfg.natsurl,
+		stream:        cfg.natsstream,
+		subjectPrefix: cfg.natssubjectprefix,
+	}
+
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *natsSink) connect() error {
+	conn, err := nats.Connect(s.url,
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(natsReconnectWait),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			log.Warn("Reconnected to NATS at ", c.ConnectedUrl())
+		}),
+		nats.DisconnectErrHandler(func(c *nats.Conn, err error) {
+			log.Warn("Disconnected from NATS: ", err)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if _, err := js.AddStream(&nats.StreamConfig{
+		Name:     s.stream,
+		Subjects: []string{s.subjectPrefix + ".>"},
+	}); err != nil && err != nats.ErrStreamNameAlreadyInUse {
+		conn.Close()
+		return err
+	}
+
+	s.conn = conn
+	s.js = js
+
+	return nil
+}
+
+// publish sends a single message and waits for the JetStream ack, giving
+// at-least-once delivery.
+func (s *natsSink) publish(subject string, payload []byte) bool {
+	if _, err := s.js.Publish(subject, payload); err != nil {
+		log.Error("Error publishing to NATS subject ", subject, ": ", err)
+		return false
+	}
+
+	return true
+}
+
+func (s *natsSink) Close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// sendToNats drains r.Input into JetStream, batching and flushing on the
+// same Config.batch/Config.flush cadence as sendToInflux.
+func (r *Metrics) sendToNats() {
+	sink, err := newNatsSink(r.Config)
+	if err != nil {
+		log.Error("Error connecting to NATS ", err)
+		return
+	}
+	defer sink.Close()
+
+	var batch []Metric
+
+	// flush publishes the batch, keeping any metric whose publish failed
+	// (e.g. during a reconnect) so it's retried on the next flush instead
+	// of being silently dropped.
+	flush := func() {
+		var failed []Metric
+
+		for _, m := range batch {
+			payload, err := m.payload()
+			if err != nil {
+				log.Error("Error encoding metric for NATS ", err)
+				continue
+			}
+			if !sink.publish(m.subject(), payload) {
+				failed = append(failed, m)
+			}
+		}
+
+		batch = failed
+	}
+
+	ticker := time.NewTicker(time.Second * time.Duration(r.Config.flush))
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			flush()
+			return
+		case <-ticker.C:
+			if len(batch) > 0 {
+				log.Debug("Tick on sending to NATS")
+				flush()
+			}
+		case p := <-r.Input:
+			if p != nil {
+				batch = append(batch, p)
+				if len(batch) >= r.Config.batch {
+					flush()
+				}
+			} else {
+				flush()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package metrics
+
+import "time"
+
+// Point is a backend-neutral measurement: a measurement name, its tag and
+// field sets, and a timestamp. Metric implementations produce these so the
+// Metric interface doesn't have to leak a specific Influx client's type.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	repoStars  = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "github_repo_stars", Help: "Repository stargazer count"}, []string{"org", "repo"})
+	repoForks  = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "github_repo_forks", Help: "Repository fork count"}, []string{"org", "repo"})
+	repoIssues = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "github_repo_open_issues", Help: "Repository open issue count"}, []string{"org", "repo"})
+
+	releaseDownloads = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "github_release_asset_downloads", Help: "Release asset download count"}, []string{"org", "repo", "release", "asset"})
+
+	registerRepoOnce    sync.Once
+	registerReleaseOnce sync.Once
+
+	releaseAssetsMu   sync.Mutex
+	releaseSeenAssets = map[string]map[string]bool{}
+
+	releaseNamesMu    sync.Mutex
+	knownReleaseNames = map[string]map[string]bool{}
+)
+
+// servePrometheus registers the collectors, serves them over HTTP and
+// updates them from Input until the Input channel is closed. In this output
+// mode getRepoData and getReleaseData ignore Config.once and keep refreshing,
+// so Input is only closed once the context is cancelled.
+func (r *Metrics) servePrometheus() {
+	registry := prometheus.NewRegistry()
+
+	server := &http.Server{
+		Addr:    r.Config.listenAddr,
+		Handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+
+	go func() {
+		log.Infof("Serving Prometheus metrics on %s", r.Config.listenAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("Error serving Prometheus metrics ", err)
+		}
+	}()
+
+	for metric := range r.Input {
+		if metric == nil {
+			continue
+		}
+		metric.registerPrometheus(registry)
+		metric.updatePrometheus()
+	}
+}
+
+func (r *Repo) registerPrometheus(registry *prometheus.Registry) {
+	registerRepoOnce.Do(func() {
+		registry.MustRegister(repoStars, repoForks, repoIssues)
+	})
+}
+
+func (r *Repo) updatePrometheus() {
+	repoStars.WithLabelValues(r.Org, r.Name).Set(float64(r.StargazersCount))
+	repoForks.WithLabelValues(r.Org, r.Name).Set(float64(r.ForksCount))
+	repoIssues.WithLabelValues(r.Org, r.Name).Set(float64(r.OpenIssuesCount))
+}
+
+func (r *Release) registerPrometheus(registry *prometheus.Registry) {
+	registerReleaseOnce.Do(func() {
+		registry.MustRegister(releaseDownloads)
+	})
+}
+
+// updatePrometheus sets a gauge per asset and unregisters any asset label
+// set that was present on a previous tick but has since disappeared (e.g.
+// the asset was deleted upstream), so stale series don't leak. A release
+// that disappears entirely is handled by reconcileReleasePrometheus.
+func (r *Release) updatePrometheus() {
+	key := r.Org + "/" + r.Repo + "/" + r.Name
+
+	seen := map[string]bool{}
+	for _, a := range *r.Assets {
+		releaseDownloads.WithLabelValues(r.Org, r.Repo, r.Name, a.Name).Set(float64(a.DownloadCount))
+		seen[a.Name] = true
+	}
+
+	releaseAssetsMu.Lock()
+	defer releaseAssetsMu.Unlock()
+
+	for asset := range releaseSeenAssets[key] {
+		if !seen[asset] {
+			releaseDownloads.DeleteLabelValues(r.Org, r.Repo, r.Name, asset)
+		}
+	}
+	releaseSeenAssets[key] = seen
+}
+
+// reconcileReleasePrometheus unregisters the whole label set for any release
+// that was known for org/repo on a previous tick but is absent from
+// currentNames, e.g. because it was deleted upstream and filterReleases no
+// longer emits a Release for it at all. Without this, a deleted release's
+// gauges would never be touched again and would leak forever.
+func reconcileReleasePrometheus(org, repo string, currentNames []string) {
+	current := map[string]bool{}
+	for _, name := range currentNames {
+		current[name] = true
+	}
+
+	repoKey := org + "/" + repo
+
+	releaseNamesMu.Lock()
+	defer releaseNamesMu.Unlock()
+
+	for name := range knownReleaseNames[repoKey] {
+		if current[name] {
+			continue
+		}
+
+		releaseKey := org + "/" + repo + "/" + name
+
+		releaseAssetsMu.Lock()
+		for asset := range releaseSeenAssets[releaseKey] {
+			releaseDownloads.DeleteLabelValues(org, repo, name, asset)
+		}
+		delete(releaseSeenAssets, releaseKey)
+		releaseAssetsMu.Unlock()
+	}
+
+	knownReleaseNames[repoKey] = current
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	releaseMinor = iota
+	releasePatch
+)
+
+// Asset represents a single release asset and its download count
+type Asset struct {
+	Name          string `json:"name"`
+	DownloadCount int    `json:"download_count"`
+}
+
+// Release represents a Github release and the assets we track on it
+type Release struct {
+	Org        string   `json:"-"`
+	Repo       string   `json:"-"`
+	Name       string   `json:"tag_name"`
+	Prerelease bool     `json:"prerelease"`
+	Assets     *[]Asset `json:"assets"`
+}
+
+// getName truncates the release name down to the requested granularity,
+// dropping a leading "v", e.g. v1.2.3 -> 1.2 for releaseMinor and
+// v1.2.3-rc.1 -> 1.2.3-rc for releasePatch. A name with too few parts is
+// returned unchanged.
+func (r *Release) getName(level int) string {
+	parts := strings.Split(strings.TrimPrefix(r.Name, "v"), ".")
+
+	switch level {
+	case releaseMinor:
+		if len(parts) >= 2 {
+			return strings.Join(parts[:2], ".")
+		}
+	case releasePatch:
+		if len(parts) >= 3 {
+			return strings.Join(parts[:3], ".")
+		}
+	}
+
+	return r.Name
+}
+
+// filterAssets drops any asset whose name doesn't match the glob
+func (r *Release) filterAssets(match string) {
+	if match == "" || r.Assets == nil {
+		return
+	}
+
+	filtered := []Asset{}
+	for _, a := range *r.Assets {
+		if ok, _ := filepath.Match(match, a.Name); ok {
+			filtered = append(filtered, a)
+		}
+	}
+	r.Assets = &filtered
+}
+
+// aggregateAssets merges other's assets into r, summing download counts for
+// assets that share a name.
+func (r *Release) aggregateAssets(other *Release) {
+	if other.Assets == nil {
+		return
+	}
+	if r.Assets == nil {
+		r.Assets = &[]Asset{}
+	}
+
+	for _, oa := range *other.Assets {
+		found := false
+		for i, a := range *r.Assets {
+			if a.Name == oa.Name {
+				(*r.Assets)[i].DownloadCount += oa.DownloadCount
+				found = true
+				break
+			}
+		}
+		if !found {
+			*r.Assets = append(*r.Assets, oa)
+		}
+	}
+}
+
+func (r *Release) printJSON() {
+	for _, a := range *r.Assets {
+		fmt.Printf("{\"org\":%q,\"repo\":%q,\"release\":%q,\"asset\":%q,\"downloads\":%d}\n",
+			r.Org, r.Repo, r.Name, a.Name, a.DownloadCount)
+	}
+}
+
+func (r *Release) printCSV() {
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = []rune(csvSeparator)[0]
+	defer w.Flush()
+
+	for _, a := range *r.Assets {
+		if err := w.Write([]string{
+			r.Org, r.Repo, r.Name, a.Name, fmt.Sprint(a.DownloadCount),
+		}); err != nil {
+			log.Error("Error writing release CSV ", err)
+		}
+	}
+}
+
+func (r *Release) printInflux() {
+	for _, p := range r.getPoint() {
+		fmt.Printf("%s,org=%s,repo=%s,release=%s,asset=%s downloads=%v\n",
+			p.Measurement, p.Tags["org"], p.Tags["repo"], p.Tags["release"], p.Tags["asset"], p.Fields["downloads"])
+	}
+}
+
+// subject is the JetStream subject a release metric is published under.
+func (r *Release) subject() string {
+	return natsSubjectPrefix + "." + r.Org + "." + r.Repo + ".release." + r.Name
+}
+
+func (r *Release) payload() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *Release) getPoint() []Point {
+	points := []Point{}
+	now := time.Now()
+
+	for _, a := range *r.Assets {
+		points = append(points, Point{
+			Measurement: "release",
+			Tags: map[string]string{
+				"org":     r.Org,
+				"repo":    r.Repo,
+				"release": r.Name,
+				"asset":   a.Name,
+			},
+			Fields: map[string]interface{}{
+				"downloads": a.DownloadCount,
+			},
+			Time: now,
+		})
+	}
+
+	return points
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Repo represents the Github repository metrics we care about
+type Repo struct {
+	Org             string `json:"-"`
+	Name            string `json:"name"`
+	StargazersCount int    `json:"stargazers_count"`
+	ForksCount      int    `json:"forks_count"`
+	OpenIssuesCount int    `json:"open_issues_count"`
+}
+
+func (r *Repo) printJSON() {
+	fmt.Printf("{\"org\":%q,\"repo\":%q,\"stars\":%d,\"forks\":%d,\"open_issues\":%d}\n",
+		r.Org, r.Name, r.StargazersCount, r.ForksCount, r.OpenIssuesCount)
+}
+
+func (r *Repo) printCSV() {
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = []rune(csvSeparator)[0]
+	defer w.Flush()
+
+	if err := w.Write([]string{
+		r.Org, r.Name,
+		fmt.Sprint(r.StargazersCount),
+		fmt.Sprint(r.ForksCount),
+		fmt.Sprint(r.OpenIssuesCount),
+	}); err != nil {
+		log.Error("Error writing repo CSV ", err)
+	}
+}
+
+func (r *Repo) printInflux() {
+	for _, p := range r.getPoint() {
+		fmt.Printf("%s,org=%s,repo=%s stars=%d,forks=%d,open_issues=%d\n",
+			p.Measurement, p.Tags["org"], p.Tags["repo"],
+			r.StargazersCount, r.ForksCount, r.OpenIssuesCount)
+	}
+}
+
+// subject is the JetStream subject a repo metric is published under.
+func (r *Repo) subject() string {
+	return natsSubjectPrefix + "." + r.Org + "." + r.Name + ".repo"
+}
+
+func (r *Repo) payload() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *Repo) getPoint() []Point {
+	return []Point{{
+		Measurement: "repo",
+		Tags: map[string]string{
+			"org":  r.Org,
+			"repo": r.Name,
+		},
+		Fields: map[string]interface{}{
+			"stars":       r.StargazersCount,
+			"forks":       r.ForksCount,
+			"open_issues": r.OpenIssuesCount,
+		},
+		Time: time.Now(),
+	}}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getJSONRetry wraps getJSON with exponential backoff and Github rate-limit
+// awareness, so a single transient 403/429/5xx doesn't drop a tick's data.
+// It gives up and returns the last error after Config.retryLimit attempts,
+// and honours ctx cancellation between attempts so a stop signal doesn't
+// have to wait out a sleep.
+func getJSONRetry(ctx context.Context, url, user, token string, insecure bool, target interface{}, cfg *Config) (string, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < cfg.retryLimit; attempt++ {
+		next, err := getJSON(ctx, url, user, token, insecure, target)
+		if err == nil {
+			return next, nil
+		}
+		lastErr = err
+
+		delay, retryable := retryDelay(err, attempt, cfg)
+		if !retryable {
+			return "", err
+		}
+
+		log.Warnf("Retrying %s in %s (attempt %d/%d): %v", url, delay, attempt+1, cfg.retryLimit, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return "", lastErr
+}
+
+// retryDelay decides how long to wait before the next attempt, and whether
+// the error is worth retrying at all.
+func retryDelay(err error, attempt int, cfg *Config) (time.Duration, bool) {
+	statusErr, ok := err.(*httpStatusError)
+	if !ok {
+		// Transient network error.
+		return backoff(attempt, cfg), true
+	}
+
+	switch {
+	case statusErr.status == http.StatusTooManyRequests:
+		return rateLimitDelay(statusErr.headers, cfg), true
+	case statusErr.status == http.StatusForbidden && statusErr.headers.Get("X-RateLimit-Remaining") == "0":
+		return rateLimitDelay(statusErr.headers, cfg), true
+	case statusErr.status >= 500:
+		return backoff(attempt, cfg), true
+	default:
+		return 0, false
+	}
+}
+
+func backoff(attempt int, cfg *Config) time.Duration {
+	delay := cfg.retryBase * time.Duration(int64(1)<<uint(attempt))
+	if delay > cfg.retryCap {
+		delay = cfg.retryCap
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(cfg.retryBase)+1))
+}
+
+// rateLimitDelay waits until Github tells us it's safe to retry, preferring
+// Retry-After and falling back to X-RateLimit-Reset.
+func rateLimitDelay(headers http.Header, cfg *Config) time.Duration {
+	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+
+	if reset := headers.Get("X-RateLimit-Reset"); reset != "" {
+		if epoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			if d := time.Until(time.Unix(epoch, 0)); d > 0 {
+				return d
+			}
+		}
+	}
+
+	return cfg.retryCap
+}
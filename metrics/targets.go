@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"os"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Target is a single repository to watch, with optional per-repo overrides
+// of the global release filtering flags.
+type Target struct {
+	Org  string `yaml:"org"`
+	Repo string `yaml:"repo"`
+
+	Match      string `yaml:"match,omitempty"`
+	Minor      *bool  `yaml:"minor,omitempty"`
+	Patch      *bool  `yaml:"patch,omitempty"`
+	Prerelease *bool  `yaml:"prerelease,omitempty"`
+}
+
+func (t Target) effectiveMatch(cfg *Config) string {
+	if t.Match != "" {
+		return t.Match
+	}
+	return cfg.match
+}
+
+func (t Target) effectiveMinor(cfg *Config) bool {
+	if t.Minor != nil {
+		return *t.Minor
+	}
+	return cfg.minor
+}
+
+func (t Target) effectivePatch(cfg *Config) bool {
+	if t.Patch != nil {
+		return *t.Patch
+	}
+	return cfg.patch
+}
+
+func (t Target) effectivePrerelease(cfg *Config) bool {
+	if t.Prerelease != nil {
+		return *t.Prerelease
+	}
+	return cfg.prerelease
+}
+
+// targetsConfig is the on-disk shape of the --config YAML file.
+type targetsConfig struct {
+	Targets []Target `yaml:"targets"`
+}
+
+// loadTargets reads and parses the targets YAML file pointed to by path.
+func loadTargets(path string) ([]Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &targetsConfig{}
+	if err := yaml.Unmarshal(data, tc); err != nil {
+		return nil, err
+	}
+
+	return tc.Targets, nil
+}
+
+// targets resolves the repos this run should watch: the ones loaded from
+// Config.targets, or a single target built from the legacy org/repo flags.
+func (r *Metrics) targets() []Target {
+	if len(r.Config.targets) > 0 {
+		return r.Config.targets
+	}
+
+	return []Target{{Org: r.Config.org, Repo: r.Config.repo}}
+}